@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Melkiyjan/go-project/internal/notes"
+)
+
+// apiNotesHandler implements GET (paginated list) and POST (create) on
+// /api/v1/notes.
+func (a *App) apiNotesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.apiListNotes(w, r)
+	case http.MethodPost:
+		if !a.authorizedForWrite(r) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		a.apiCreateNote(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authorizedForWrite reports whether a mutating API request may proceed.
+// Without -me configured there is no owner identity to check against,
+// so writes are refused rather than left open.
+func (a *App) authorizedForWrite(r *http.Request) bool {
+	return a.Auth != nil && a.Auth.Authorized(r)
+}
+
+// apiNoteHandler implements GET, PUT and DELETE on /api/v1/notes/{id}.
+func (a *App) apiNoteHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/notes/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodPut || r.Method == http.MethodDelete {
+		if !a.authorizedForWrite(r) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		a.apiGetNote(w, r, id)
+	case http.MethodPut:
+		a.apiUpdateNote(w, r, id)
+	case http.MethodDelete:
+		a.apiDeleteNote(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *App) apiListNotes(w http.ResponseWriter, r *http.Request) {
+	p := parsePageParams(r)
+
+	list, total, err := a.listNotes(r.Context(), p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pages := totalPages(total, p.PerPage)
+
+	setPaginationHeaders(w, r, p, pages)
+
+	writeJSON(w, http.StatusOK, IndexPageData{
+		Notes:      list,
+		Page:       p.Page,
+		HasPrev:    p.Page > 1,
+		HasNext:    p.Page < pages,
+		PrevPage:   p.Page - 1,
+		NextPage:   p.Page + 1,
+		Total:      total,
+		TotalPages: pages,
+		PerPage:    p.PerPage,
+	})
+}
+
+func (a *App) apiGetNote(w http.ResponseWriter, r *http.Request, id int64) {
+	n, err := a.Notes.Get(r.Context(), id)
+	if err == notes.ErrNotFound {
+		http.Error(w, "Note not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
+
+func (a *App) apiCreateNote(w http.ResponseWriter, r *http.Request) {
+	var n Note
+	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if n.Title == "" || n.Content == "" {
+		http.Error(w, "title and content are required", http.StatusBadRequest)
+		return
+	}
+
+	id, err := a.createNote(r.Context(), n.Title, n.Content)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	n.Id = id
+
+	w.Header().Set("Location", fmt.Sprintf("/note?id=%d", id))
+	writeJSON(w, http.StatusCreated, n)
+}
+
+func (a *App) apiUpdateNote(w http.ResponseWriter, r *http.Request, id int64) {
+	var n Note
+	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if n.Title == "" || n.Content == "" {
+		http.Error(w, "title and content are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.updateNote(r.Context(), id, n.Title, n.Content); err != nil {
+		if err == notes.ErrNotFound {
+			http.Error(w, "Note not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	n.Id = id
+
+	writeJSON(w, http.StatusOK, n)
+}
+
+func (a *App) apiDeleteNote(w http.ResponseWriter, r *http.Request, id int64) {
+	if err := a.deleteNote(r.Context(), id); err != nil {
+		if err == notes.ErrNotFound {
+			http.Error(w, "Note not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Ошибка кодирования JSON: %v", err)
+	}
+}
+
+// createNote, updateNote and deleteNote are the shared repository +
+// side-effect (search index, backlinks) operations behind both the
+// HTML handlers and the JSON/Micropub API.
+
+func (a *App) createNote(ctx context.Context, title, content string) (int64, error) {
+	id, err := a.Notes.Create(ctx, title, content)
+	if err != nil {
+		return 0, err
+	}
+
+	a.indexNote(Note{Id: id, Title: title, Content: content})
+	if err := a.syncNoteLinks(id, content); err != nil {
+		log.Printf("Ошибка синхронизации ссылок заметки %d: %v", id, err)
+	}
+	if err := a.backfillNoteLinks(id, title); err != nil {
+		log.Printf("Ошибка обратного связывания ссылок заметки %d: %v", id, err)
+	}
+
+	return id, nil
+}
+
+func (a *App) updateNote(ctx context.Context, id int64, title, content string) error {
+	if err := a.Notes.Update(ctx, id, title, content); err != nil {
+		return err
+	}
+
+	a.indexNote(Note{Id: id, Title: title, Content: content})
+	if err := a.syncNoteLinks(id, content); err != nil {
+		log.Printf("Ошибка синхронизации ссылок заметки %d: %v", id, err)
+	}
+	if err := a.backfillNoteLinks(id, title); err != nil {
+		log.Printf("Ошибка обратного связывания ссылок заметки %d: %v", id, err)
+	}
+
+	return nil
+}
+
+func (a *App) deleteNote(ctx context.Context, id int64) error {
+	if err := a.Notes.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	a.deindexNote(id)
+	if _, err := a.DB.Exec("DELETE FROM note_links WHERE src_id = ? OR dst_id = ?", id, id); err != nil {
+		log.Printf("Ошибка удаления ссылок заметки %d: %v", id, err)
+	}
+
+	return nil
+}
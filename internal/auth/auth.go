@@ -0,0 +1,231 @@
+// Package auth implements single-user sign-in via IndieAuth, protecting
+// the note-editing routes behind a session cookie.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"p83.nl/go/indieauth"
+)
+
+type contextKey string
+
+const identityKey contextKey = "auth.identity"
+
+const sessionCookie = "session"
+
+// Session tracks a signed-in IndieAuth identity.
+type Session struct {
+	Me      string
+	Token   string
+	Expires time.Time
+}
+
+// loginState tracks a pending login: when the state token expires, and
+// where to send the browser back to once it's redeemed.
+type loginState struct {
+	expires  time.Time
+	returnTo string
+}
+
+// Auth validates sessions and runs the IndieAuth login flow for a
+// single configured owner (Me).
+type Auth struct {
+	Client   indieauth.Client
+	Me       string
+	APIToken string
+
+	mu       sync.Mutex
+	sessions map[string]Session
+	states   map[string]loginState
+}
+
+// New builds an Auth for the given base URL and owner profile URL. The
+// API token, if set via API_TOKEN, authorizes bearer requests without a
+// session cookie (e.g. from external editors).
+func New(baseURL, me string) *Auth {
+	return &Auth{
+		Client: indieauth.Client{
+			ID:          baseURL + "/",
+			RedirectURL: baseURL + "/callback",
+		},
+		Me:       me,
+		APIToken: os.Getenv("API_TOKEN"),
+		sessions: make(map[string]Session),
+		states:   make(map[string]loginState),
+	}
+}
+
+// loginURL builds the /login redirect for an unauthenticated request,
+// carrying the original path and query through ?return= so the user
+// lands back where they started once signed in.
+func loginURL(r *http.Request) string {
+	v := url.Values{"return": {r.URL.RequestURI()}}
+	return "/login?" + v.Encode()
+}
+
+// returnPath validates a ?return= value against being used for an open
+// redirect, falling back to "/" for anything but a same-site path.
+func returnPath(raw string) string {
+	if raw == "" || raw[0] != '/' || strings.HasPrefix(raw, "//") {
+		return "/"
+	}
+	return raw
+}
+
+// Middleware requires a valid session cookie or API bearer token,
+// otherwise redirecting to /login.
+func (a *Auth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.APIToken != "" && bearerToken(r) == a.APIToken {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey, a.Me)))
+			return
+		}
+
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			http.Redirect(w, r, loginURL(r), http.StatusSeeOther)
+			return
+		}
+
+		a.mu.Lock()
+		sess, ok := a.sessions[cookie.Value]
+		a.mu.Unlock()
+		if !ok || time.Now().After(sess.Expires) {
+			http.Redirect(w, r, loginURL(r), http.StatusSeeOther)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey, sess.Me)))
+	})
+}
+
+// Authorized reports whether the request carries a valid session cookie
+// or bearer token, without redirecting. Intended for API handlers that
+// need to return 401 instead of a login redirect.
+func (a *Auth) Authorized(r *http.Request) bool {
+	if a.APIToken != "" && bearerToken(r) == a.APIToken {
+		return true
+	}
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return false
+	}
+
+	a.mu.Lock()
+	sess, ok := a.sessions[cookie.Value]
+	a.mu.Unlock()
+
+	return ok && time.Now().Before(sess.Expires)
+}
+
+// Identity returns the signed-in profile URL stored by Middleware, if any.
+func Identity(ctx context.Context) (string, bool) {
+	me, ok := ctx.Value(identityKey).(string)
+	return me, ok
+}
+
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if len(h) > len(prefix) && h[:len(prefix)] == prefix {
+		return h[len(prefix):]
+	}
+	return ""
+}
+
+// LoginHandler starts the IndieAuth flow, redirecting the browser to
+// the owner's chosen authorization endpoint.
+func (a *Auth) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	state := randomToken()
+
+	a.mu.Lock()
+	a.states[state] = loginState{
+		expires:  time.Now().Add(10 * time.Minute),
+		returnTo: returnPath(r.URL.Query().Get("return")),
+	}
+	a.mu.Unlock()
+
+	authURL, err := a.Client.AuthorizationURL(r.Context(), a.Me, []string{"profile"}, state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, authURL.String(), http.StatusSeeOther)
+}
+
+// CallbackHandler exchanges the authorization code for a token, then
+// issues a session cookie for a.Me.
+func (a *Auth) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+
+	a.mu.Lock()
+	login, ok := a.states[state]
+	delete(a.states, state)
+	a.mu.Unlock()
+
+	if !ok || time.Now().After(login.expires) {
+		http.Error(w, "неизвестный или истёкший state", http.StatusBadRequest)
+		return
+	}
+
+	code := r.URL.Query().Get("code")
+	token, err := a.Client.RedeemCode(r.Context(), code, a.Me)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sessionID := randomToken()
+	a.mu.Lock()
+	a.sessions[sessionID] = Session{Me: a.Me, Token: token, Expires: time.Now().Add(30 * 24 * time.Hour)}
+	a.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    sessionID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  time.Now().Add(30 * 24 * time.Hour),
+	})
+
+	http.Redirect(w, r, login.returnTo, http.StatusSeeOther)
+}
+
+// LogoutHandler clears the session cookie.
+func (a *Auth) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		a.mu.Lock()
+		delete(a.sessions, cookie.Value)
+		a.mu.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:   sessionCookie,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
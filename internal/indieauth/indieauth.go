@@ -0,0 +1,173 @@
+// Package indieauth is a minimal client for the IndieAuth
+// authorization flow (https://indieauth.spec.indieweb.org/), enough to
+// cover what internal/auth needs: discovering a profile's
+// authorization/token endpoints, building the authorization redirect,
+// and exchanging a code for a token.
+package indieauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Client is an IndieAuth relying party.
+type Client struct {
+	ID          string
+	RedirectURL string
+}
+
+// endpoints are the authorization/token endpoints discovered from a
+// user's profile URL, per the IndieAuth discovery rules.
+type endpoints struct {
+	authorization string
+	token         string
+}
+
+var linkRelRe = regexp.MustCompile(`(?i)<link[^>]+>`)
+var relAttrRe = regexp.MustCompile(`(?i)rel\s*=\s*["']([^"']+)["']`)
+var hrefAttrRe = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+
+// discover fetches me and parses its <link rel="authorization_endpoint">
+// and <link rel="token_endpoint"> tags.
+func discover(ctx context.Context, me string) (endpoints, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, me, nil)
+	if err != nil {
+		return endpoints{}, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return endpoints{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return endpoints{}, fmt.Errorf("indieauth: fetch %s: status %s", me, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return endpoints{}, err
+	}
+
+	var eps endpoints
+	for _, tag := range linkRelRe.FindAllString(string(body), -1) {
+		rel := relAttrRe.FindStringSubmatch(tag)
+		href := hrefAttrRe.FindStringSubmatch(tag)
+		if rel == nil || href == nil {
+			continue
+		}
+
+		resolved, err := resolveURL(me, href[1])
+		if err != nil {
+			continue
+		}
+
+		switch rel[1] {
+		case "authorization_endpoint":
+			eps.authorization = resolved
+		case "token_endpoint":
+			eps.token = resolved
+		}
+	}
+
+	if eps.authorization == "" {
+		return endpoints{}, fmt.Errorf("indieauth: %s has no rel=authorization_endpoint link", me)
+	}
+	return eps, nil
+}
+
+func resolveURL(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
+
+// AuthorizationURL discovers me's authorization endpoint and returns
+// the URL to redirect the browser to, requesting scope and carrying
+// state back to the redirect URI.
+func (c Client) AuthorizationURL(ctx context.Context, me string, scope []string, state string) (*url.URL, error) {
+	eps, err := discover(ctx, me)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := url.Parse(eps.authorization)
+	if err != nil {
+		return nil, fmt.Errorf("indieauth: parse authorization endpoint: %w", err)
+	}
+
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.ID)
+	q.Set("redirect_uri", c.RedirectURL)
+	q.Set("state", state)
+	q.Set("me", me)
+	if len(scope) > 0 {
+		q.Set("scope", strings.Join(scope, " "))
+	}
+	authURL.RawQuery = q.Encode()
+
+	return authURL, nil
+}
+
+// RedeemCode discovers me's token endpoint and exchanges code for an
+// access token.
+func (c Client) RedeemCode(ctx context.Context, code, me string) (string, error) {
+	eps, err := discover(ctx, me)
+	if err != nil {
+		return "", err
+	}
+	if eps.token == "" {
+		return "", fmt.Errorf("indieauth: %s has no rel=token_endpoint link", me)
+	}
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"client_id":    {c.ID},
+		"redirect_uri": {c.RedirectURL},
+		"me":           {me},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, eps.token, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("indieauth: redeem code: status %s", resp.Status)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("indieauth: decode token response: %w", err)
+	}
+	if payload.AccessToken == "" {
+		return "", fmt.Errorf("indieauth: token response missing access_token")
+	}
+
+	return payload.AccessToken, nil
+}
@@ -0,0 +1,58 @@
+// Package link parses wiki-style [[Title]] references out of note
+// content and renders them as HTML anchors.
+package link
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var linkPattern = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+
+// Token is a single [[Title]] reference found in note content.
+type Token struct {
+	Title string
+	Line  int
+}
+
+// Scan finds every [[Title]] token in content along with the 1-based
+// line it appears on.
+func Scan(content string) []Token {
+	var tokens []Token
+	for i, line := range strings.Split(content, "\n") {
+		for _, m := range linkPattern.FindAllStringSubmatch(line, -1) {
+			tokens = append(tokens, Token{Title: strings.TrimSpace(m[1]), Line: i + 1})
+		}
+	}
+	return tokens
+}
+
+// Resolver maps a note title to its id, if a note with that title exists.
+type Resolver func(title string) (id int64, ok bool)
+
+// Render escapes content and turns [[Title]] tokens into anchors,
+// resolving each title via resolve. Unresolved titles render with the
+// "broken-link" class and link to /new-note to create the missing note.
+func Render(content string, resolve Resolver) template.HTML {
+	var b strings.Builder
+	last := 0
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(content, -1) {
+		b.WriteString(template.HTMLEscapeString(content[last:m[0]]))
+
+		title := strings.TrimSpace(content[m[2]:m[3]])
+		if id, ok := resolve(title); ok {
+			fmt.Fprintf(&b, `<a href="/note?id=%d">%s</a>`, id, template.HTMLEscapeString(title))
+		} else {
+			fmt.Fprintf(&b, `<a class="broken-link" href="/new-note?title=%s">%s</a>`,
+				url.QueryEscape(title), template.HTMLEscapeString(title))
+		}
+
+		last = m[1]
+	}
+	b.WriteString(template.HTMLEscapeString(content[last:]))
+
+	return template.HTML(b.String())
+}
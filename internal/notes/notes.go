@@ -0,0 +1,47 @@
+// Package notes is the storage layer for notes: the Note type, a
+// Repository interface, and a SQLite-backed implementation with
+// migrations.
+package notes
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by Get, Update and Delete when no note
+// matches the given id.
+var ErrNotFound = errors.New("notes: not found")
+
+type Note struct {
+	Id      int64  `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// ListParams bounds and orders a paginated listing. Sort and Order are
+// validated against an allow-list by the Repository implementation, so
+// callers may pass untrusted query-string values through directly.
+type ListParams struct {
+	Page    int
+	PerPage int
+	Sort    string // "id" or "title"
+	Order   string // "asc" or "desc"
+}
+
+// Repository is the storage contract for notes.
+type Repository interface {
+	List(ctx context.Context, p ListParams) ([]Note, error)
+	// ListWithTotal is List plus the total note count, run together in
+	// a single transaction so Total stays consistent with the returned
+	// page under concurrent writes.
+	ListWithTotal(ctx context.Context, p ListParams) ([]Note, int, error)
+	Get(ctx context.Context, id int64) (Note, error)
+	Create(ctx context.Context, title, content string) (int64, error)
+	Update(ctx context.Context, id int64, title, content string) error
+	Delete(ctx context.Context, id int64) error
+	// Search fetches the notes matching ids (e.g. from a full-text
+	// search index), preserving the order of ids and skipping any that
+	// no longer exist.
+	Search(ctx context.Context, ids []int64) ([]Note, error)
+	Count(ctx context.Context) (int, error)
+}
@@ -0,0 +1,230 @@
+package notes
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// allowedSort is the column allow-list for List's ORDER BY, so a
+// request's ?sort= value never reaches the query string directly.
+var allowedSort = map[string]string{
+	"id":    "id",
+	"title": "title",
+}
+
+// SQLiteRepository is a Repository backed by database/sql, with
+// prepared statements cached for the fixed-shape queries.
+type SQLiteRepository struct {
+	db *sql.DB
+
+	stmtGet    *sql.Stmt
+	stmtCreate *sql.Stmt
+	stmtUpdate *sql.Stmt
+	stmtDelete *sql.Stmt
+	stmtCount  *sql.Stmt
+}
+
+// NewSQLiteRepository prepares every fixed-shape statement up front.
+// db must already have migrations applied (see Migrate).
+func NewSQLiteRepository(db *sql.DB) (*SQLiteRepository, error) {
+	r := &SQLiteRepository{db: db}
+
+	stmts := []struct {
+		dst   **sql.Stmt
+		query string
+	}{
+		{&r.stmtGet, "SELECT id, title, content FROM notes WHERE id = ?"},
+		{&r.stmtCreate, "INSERT INTO notes (title, content) VALUES (?, ?)"},
+		{&r.stmtUpdate, "UPDATE notes SET title = ?, content = ? WHERE id = ?"},
+		{&r.stmtDelete, "DELETE FROM notes WHERE id = ?"},
+		{&r.stmtCount, "SELECT COUNT(*) FROM notes"},
+	}
+
+	for _, s := range stmts {
+		stmt, err := db.Prepare(s.query)
+		if err != nil {
+			return nil, fmt.Errorf("notes: prepare %q: %w", s.query, err)
+		}
+		*s.dst = stmt
+	}
+
+	return r, nil
+}
+
+// Close releases the prepared statements. It does not close db.
+func (r *SQLiteRepository) Close() error {
+	for _, stmt := range []*sql.Stmt{r.stmtGet, r.stmtCreate, r.stmtUpdate, r.stmtDelete, r.stmtCount} {
+		if err := stmt.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderBy resolves p's sort column against allowedSort and its
+// direction, so a request's ?sort=/?order= never reach the query
+// string directly.
+func orderBy(p ListParams) (col, direction string) {
+	col, ok := allowedSort[p.Sort]
+	if !ok {
+		col = "id"
+	}
+	direction = "ASC"
+	if p.Order == "desc" {
+		direction = "DESC"
+	}
+	return col, direction
+}
+
+// listPage runs the page query against q, a *sql.DB or *sql.Tx.
+func listPage(ctx context.Context, q interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}, p ListParams) ([]Note, error) {
+	sortCol, order := orderBy(p)
+
+	query := fmt.Sprintf("SELECT id, title, content FROM notes ORDER BY %s %s LIMIT ? OFFSET ?", sortCol, order)
+	rows, err := q.QueryContext(ctx, query, p.PerPage, (p.Page-1)*p.PerPage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Note
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.Id, &n.Title, &n.Content); err != nil {
+			return nil, err
+		}
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+// List's ORDER BY column/direction vary, so it can't use a cached
+// prepared statement; the column is taken only from allowedSort and the
+// direction is always ASC or DESC (see orderBy).
+func (r *SQLiteRepository) List(ctx context.Context, p ListParams) ([]Note, error) {
+	return listPage(ctx, r.db, p)
+}
+
+// ListWithTotal runs the page query and COUNT(*) inside one
+// transaction, so Total can't drift from the returned page if a note
+// is created or deleted in between.
+func (r *SQLiteRepository) ListWithTotal(ctx context.Context, p ListParams) ([]Note, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	list, err := listPage(ctx, tx, p)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM notes").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (r *SQLiteRepository) Get(ctx context.Context, id int64) (Note, error) {
+	var n Note
+	err := r.stmtGet.QueryRowContext(ctx, id).Scan(&n.Id, &n.Title, &n.Content)
+	if err == sql.ErrNoRows {
+		return Note{}, ErrNotFound
+	}
+	return n, err
+}
+
+func (r *SQLiteRepository) Create(ctx context.Context, title, content string) (int64, error) {
+	res, err := r.stmtCreate.ExecContext(ctx, title, content)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *SQLiteRepository) Update(ctx context.Context, id int64, title, content string) error {
+	res, err := r.stmtUpdate.ExecContext(ctx, title, content, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.stmtDelete.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) Search(ctx context.Context, ids []int64) ([]Note, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	query := fmt.Sprintf("SELECT id, title, content FROM notes WHERE id IN (%s)", placeholders)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]Note, len(ids))
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.Id, &n.Title, &n.Content); err != nil {
+			return nil, err
+		}
+		byID[n.Id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ordered := make([]Note, 0, len(ids))
+	for _, id := range ids {
+		if n, ok := byID[id]; ok {
+			ordered = append(ordered, n)
+		}
+	}
+	return ordered, nil
+}
+
+func (r *SQLiteRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.stmtCount.QueryRowContext(ctx).Scan(&count)
+	return count, err
+}
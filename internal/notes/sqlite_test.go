@@ -0,0 +1,183 @@
+package notes
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestRepo(t *testing.T) *SQLiteRepository {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repo, err := NewSQLiteRepository(db)
+	if err != nil {
+		t.Fatalf("new repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	return repo
+}
+
+func TestCreateGetUpdateDelete(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, "Первая", "Содержимое")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Title != "Первая" || got.Content != "Содержимое" {
+		t.Fatalf("unexpected note: %+v", got)
+	}
+
+	if err := repo.Update(ctx, id, "Изменена", "Новое содержимое"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	got, err = repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("get after update: %v", err)
+	}
+	if got.Title != "Изменена" || got.Content != "Новое содержимое" {
+		t.Fatalf("update did not persist: %+v", got)
+	}
+
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if _, err := repo.Get(ctx, id); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestUpdateAndDeleteMissingNote(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if err := repo.Update(ctx, 999, "x", "y"); err != ErrNotFound {
+		t.Fatalf("update: expected ErrNotFound, got %v", err)
+	}
+	if err := repo.Delete(ctx, 999); err != ErrNotFound {
+		t.Fatalf("delete: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	for _, title := range []string{"C", "A", "B"} {
+		if _, err := repo.Create(ctx, title, "x"); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		params ListParams
+		want   []string
+	}{
+		{"sort title asc", ListParams{Page: 1, PerPage: 10, Sort: "title", Order: "asc"}, []string{"A", "B", "C"}},
+		{"sort title desc", ListParams{Page: 1, PerPage: 10, Sort: "title", Order: "desc"}, []string{"C", "B", "A"}},
+		{"unknown sort falls back to id", ListParams{Page: 1, PerPage: 10, Sort: "id; DROP TABLE notes", Order: "asc"}, []string{"C", "A", "B"}},
+		{"second page of two", ListParams{Page: 2, PerPage: 2, Sort: "title", Order: "asc"}, []string{"C"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := repo.List(ctx, tc.params)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+
+			var titles []string
+			for _, n := range got {
+				titles = append(titles, n.Title)
+			}
+			if !reflect.DeepEqual(titles, tc.want) {
+				t.Fatalf("got %v, want %v", titles, tc.want)
+			}
+		})
+	}
+}
+
+func TestListWithTotal(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	for _, title := range []string{"C", "A", "B"} {
+		if _, err := repo.Create(ctx, title, "x"); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	got, total, err := repo.ListWithTotal(ctx, ListParams{Page: 1, PerPage: 2, Sort: "title", Order: "asc"})
+	if err != nil {
+		t.Fatalf("list with total: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("total = %d, want 3", total)
+	}
+
+	var titles []string
+	for _, n := range got {
+		titles = append(titles, n.Title)
+	}
+	if !reflect.DeepEqual(titles, []string{"A", "B"}) {
+		t.Fatalf("got %v, want [A B]", titles)
+	}
+}
+
+func TestSearchPreservesOrderAndSkipsMissing(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	id1, _ := repo.Create(ctx, "Один", "x")
+	id2, _ := repo.Create(ctx, "Два", "y")
+
+	got, err := repo.Search(ctx, []int64{id2, 9999, id1})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(got) != 2 || got[0].Id != id2 || got[1].Id != id1 {
+		t.Fatalf("unexpected search result: %+v", got)
+	}
+}
+
+func TestCount(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := repo.Create(ctx, "t", "c"); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	count, err := repo.Count(ctx)
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3, got %d", count)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"database/sql"
+	"html/template"
+	"strings"
+
+	"github.com/Melkiyjan/go-project/internal/link"
+)
+
+// DetailPageData is passed to the "details" template: the note itself,
+// its content with [[backlinks]] rendered as anchors, and the notes
+// that link back to it.
+type DetailPageData struct {
+	Note
+	RenderedContent template.HTML
+	Backrefs        []Backref
+}
+
+// Backref is an inbound [[link]] from another note.
+type Backref struct {
+	SourceID    int64
+	SourceTitle string
+	Line        template.HTML
+}
+
+// resolveTitle looks up a note's id by its exact title, for use as a
+// link.Resolver.
+func (a *App) resolveTitle(title string) (int64, bool) {
+	var id int64
+	err := a.DB.QueryRow("SELECT id FROM notes WHERE title = ?", title).Scan(&id)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// syncNoteLinks replaces the note_links rows for srcID with the
+// [[Title]] tokens found in content. Unresolved titles are kept with a
+// NULL dst_id and their raw title in dst_title, so backfillNoteLinks
+// can resolve them once the target note is created.
+func (a *App) syncNoteLinks(srcID int64, content string) error {
+	tx, err := a.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM note_links WHERE src_id = ?", srcID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, tok := range link.Scan(content) {
+		var dstID sql.NullInt64
+		if id, ok := a.resolveTitle(tok.Title); ok {
+			dstID = sql.NullInt64{Int64: id, Valid: true}
+		}
+
+		if _, err := tx.Exec("INSERT INTO note_links (src_id, dst_id, dst_title, line) VALUES (?, ?, ?, ?)",
+			srcID, dstID, tok.Title, tok.Line); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// backfillNoteLinks resolves any note_links left with a NULL dst_id
+// because they were created before their target note existed, now
+// that a note titled title has been created as id.
+func (a *App) backfillNoteLinks(id int64, title string) error {
+	_, err := a.DB.Exec("UPDATE note_links SET dst_id = ? WHERE dst_id IS NULL AND dst_title = ?", id, title)
+	return err
+}
+
+// backrefsFor returns every note that links to dstID, along with the
+// source line the [[link]] appeared on.
+func (a *App) backrefsFor(dstID int64) ([]Backref, error) {
+	rows, err := a.DB.Query(`
+		SELECT notes.id, notes.title, notes.content, note_links.line
+		FROM note_links
+		JOIN notes ON notes.id = note_links.src_id
+		WHERE note_links.dst_id = ?`, dstID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var backrefs []Backref
+	for rows.Next() {
+		var (
+			srcID   int64
+			title   string
+			content string
+			lineNo  int
+		)
+		if err := rows.Scan(&srcID, &title, &content, &lineNo); err != nil {
+			return nil, err
+		}
+
+		lines := strings.Split(content, "\n")
+		var line string
+		if lineNo >= 1 && lineNo <= len(lines) {
+			line = lines[lineNo-1]
+		}
+
+		backrefs = append(backrefs, Backref{
+			SourceID:    srcID,
+			SourceTitle: title,
+			Line:        template.HTML(template.HTMLEscapeString(line)),
+		})
+	}
+
+	return backrefs, rows.Err()
+}
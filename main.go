@@ -2,51 +2,63 @@ package main
 
 import (
 	"database/sql"
+	"flag"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/blevesearch/bleve/v2"
 	_ "modernc.org/sqlite"
+
+	"github.com/Melkiyjan/go-project/internal/auth"
+	"github.com/Melkiyjan/go-project/internal/link"
+	"github.com/Melkiyjan/go-project/internal/notes"
 )
 
-type Note struct {
-	Id      int64  `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
-}
+// Note is the notes package's Note type; handlers throughout main use
+// the unqualified name since they predate the package split.
+type Note = notes.Note
 
 type IndexPageData struct {
-	Notes    []Note
-	Page     int
-	HasNext  bool
-	HasPrev  bool
-	NextPage int
-	PrevPage int
+	Notes      []Note
+	Page       int
+	HasNext    bool
+	HasPrev    bool
+	NextPage   int
+	PrevPage   int
+	Total      int
+	TotalPages int
+	PerPage    int
+	PageWindow []int
+	Query      string
+	Highlights map[int64]template.HTML
+
+	// AuthEnabled reports whether -me was set, so the template only
+	// links to /login and /logout when those routes actually exist.
+	// LoggedIn reports whether the current request is signed in, so
+	// the template only offers editing links the visitor can use.
+	AuthEnabled bool
+	LoggedIn    bool
 }
 
 type App struct {
-	DB        *sql.DB
-	Templates map[string]*template.Template
+	DB          *sql.DB
+	Notes       notes.Repository
+	Templates   map[string]*template.Template
+	SearchIndex bleve.Index
+	Auth        *auth.Auth
 }
 
 func initDB() (*sql.DB, error) {
 	db, err := sql.Open("sqlite", "data.sqlite")
 	if err != nil {
-		log.Fatal("Ошибка подключения к БД:", err)
+		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
 	}
 
-	createTable := `
-    CREATE TABLE IF NOT EXISTS notes (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    title TEXT NOT NULL,
-    content TEXT NOT NULL
-                                     );`
-
-	_, err = db.Exec(createTable)
-	if err != nil {
-		log.Fatal("Ошибка создания таблицы:", err)
+	if err := notes.Migrate(db); err != nil {
+		return nil, fmt.Errorf("ошибка миграции БД: %w", err)
 	}
 
 	return db, nil
@@ -63,6 +75,7 @@ func (a *App) initTemplates() error {
 		{"add", "templates/add.html"},
 		{"details", "templates/details.html"},
 		{"update", "templates/update.html"},
+		{"search", "templates/search.html"},
 	}
 
 	for _, tmpl := range templates {
@@ -76,57 +89,31 @@ func (a *App) initTemplates() error {
 }
 
 func (a *App) mainPage(w http.ResponseWriter, r *http.Request) {
-	var notes []Note
-
-	pageStr := r.URL.Query().Get("page")
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
-	}
+	p := parsePageParams(r)
 
-	limit := 1
-	offset := (page - 1) * limit
-
-	rows, err := a.DB.Query("SELECT id, title, content FROM notes LIMIT ? OFFSET ?", limit+1, offset)
+	list, total, err := a.listNotes(r.Context(), p)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	defer func() {
-		if closeErr := rows.Close(); closeErr != nil {
-			// Логируем, но не прерываем выполнение
-			log.Printf("Ошибка при закрытии rows: %v", closeErr)
-		}
-	}()
+	pages := totalPages(total, p.PerPage)
 
-	for rows.Next() {
-		var n Note
-		if err := rows.Scan(&n.Id, &n.Title, &n.Content); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		notes = append(notes, n)
-	}
-
-	hasNext := false
-	if len(notes) > limit {
-		hasNext = true
-		notes = notes[:limit] // отрезаем лишний один
-	}
+	setPaginationHeaders(w, r, p, pages)
 
-	if err := rows.Err(); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Println(len(notes))
 	data := IndexPageData{
-		Notes:    notes,
-		Page:     page,
-		HasPrev:  page > 1,
-		HasNext:  hasNext,
-		PrevPage: page - 1,
-		NextPage: page + 1,
+		Notes:       list,
+		Page:        p.Page,
+		HasPrev:     p.Page > 1,
+		HasNext:     p.Page < pages,
+		PrevPage:    p.Page - 1,
+		NextPage:    p.Page + 1,
+		Total:       total,
+		TotalPages:  pages,
+		PerPage:     p.PerPage,
+		PageWindow:  pageWindow(p.Page, pages),
+		AuthEnabled: a.Auth != nil,
+		LoggedIn:    a.Auth != nil && a.Auth.Authorized(r),
 	}
 
 	// Отдаём HTML-страницу
@@ -137,24 +124,33 @@ func (a *App) mainPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writesDisabledHandler serves every mutating route when -me is unset:
+// there is no owner identity to sign in as, so editing stays refused
+// rather than falling open.
+func writesDisabledHandler(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "запись отключена: сервер запущен без -me", http.StatusForbidden)
+}
+
 func (a *App) addPage(w http.ResponseWriter, r *http.Request) {
+	data := Note{Title: r.URL.Query().Get("title")}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	http.ServeFile(w, r, "templates/add.html")
+	if err := a.Templates["add"].Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 }
 
 func (a *App) updatePage(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
 		http.Error(w, "ID is required", http.StatusBadRequest)
 		return
 	}
 
-	var note Note
-
-	err := a.DB.QueryRow("SELECT id, title, content FROM notes WHERE id = ?", id).
-		Scan(&note.Id, &note.Title, &note.Content)
+	note, err := a.Notes.Get(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == notes.ErrNotFound {
 			http.Error(w, "Note not found", http.StatusNotFound)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -170,18 +166,15 @@ func (a *App) updatePage(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *App) detailPage(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
 		http.Error(w, "ID is required", http.StatusBadRequest)
 		return
 	}
 
-	var note Note
-
-	err := a.DB.QueryRow("SELECT id, title, content FROM notes WHERE id = ?", id).
-		Scan(&note.Id, &note.Title, &note.Content)
+	note, err := a.Notes.Get(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == notes.ErrNotFound {
 			http.Error(w, "Note not found", http.StatusNotFound)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -189,8 +182,20 @@ func (a *App) detailPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	backrefs, err := a.backrefsFor(note.Id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := DetailPageData{
+		Note:            note,
+		RenderedContent: link.Render(note.Content, a.resolveTitle),
+		Backrefs:        backrefs,
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := a.Templates["details"].Execute(w, note); err != nil {
+	if err := a.Templates["details"].Execute(w, data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -211,13 +216,7 @@ func (a *App) createNoteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := a.DB.Exec("INSERT INTO notes (title, content) VALUES (?, ?)", title, content)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	id, err := result.LastInsertId()
+	id, err := a.createNote(r.Context(), title, content)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -227,21 +226,26 @@ func (a *App) createNoteHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *App) updateNoteHandler(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
-	if id == "" {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
 		http.Error(w, "ID is required", http.StatusBadRequest)
 		return
 	}
 
-	res, err := a.DB.Exec("UPDATE notes SET title = ?, content = ? WHERE id = ?", id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	title := r.FormValue("title")
+	content := r.FormValue("content")
+
+	if title == "" || content == "" {
+		http.Error(w, "Title and content are required", http.StatusBadRequest)
 		return
 	}
 
-	rowsAffected, _ := res.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Note not found", http.StatusNotFound)
+	if err := a.updateNote(r.Context(), id, title, content); err != nil {
+		if err == notes.ErrNotFound {
+			http.Error(w, "Note not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -255,15 +259,18 @@ func (a *App) removeNoteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := a.DB.Exec("DELETE FROM notes WHERE id = ?", id)
+	idNum, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "ID is invalid", http.StatusBadRequest)
 		return
 	}
 
-	rowsAffected, _ := res.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Note not found", http.StatusNotFound)
+	if err := a.deleteNote(r.Context(), idNum); err != nil {
+		if err == notes.ErrNotFound {
+			http.Error(w, "Note not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -279,6 +286,11 @@ func (a *App) backHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func main() {
+	reindex := flag.Bool("reindex", false, "удалить и перестроить поисковый индекс из SQLite")
+	baseURL := flag.String("baseurl", "http://localhost:8080", "публичный адрес сервера (для IndieAuth)")
+	me := flag.String("me", "", "профиль владельца заметок (IndieAuth identity)")
+	flag.Parse()
+
 	db, err := initDB()
 	if err != nil {
 		log.Fatal(err)
@@ -291,7 +303,40 @@ func main() {
 		}
 	}()
 
-	app := &App{DB: db}
+	repo, err := notes.NewSQLiteRepository(db)
+	if err != nil {
+		log.Fatal("Ошибка подготовки запросов:", err)
+	}
+	defer func() {
+		if closeErr := repo.Close(); closeErr != nil {
+			log.Printf("Ошибка при закрытии запросов: %v", closeErr)
+		}
+	}()
+
+	app := &App{DB: db, Notes: repo}
+
+	if *me != "" {
+		app.Auth = auth.New(*baseURL, *me)
+	}
+
+	if *reindex {
+		if err := app.reindexAll(); err != nil {
+			log.Fatal("Ошибка перестроения индекса:", err)
+		}
+		return
+	}
+
+	searchIndex, err := app.openSearchIndex()
+	if err != nil {
+		log.Printf("Ошибка открытия поискового индекса: %v", err)
+	} else {
+		app.SearchIndex = searchIndex
+		defer func() {
+			if closeErr := searchIndex.Close(); closeErr != nil {
+				log.Printf("Ошибка при закрытии поискового индекса: %v", closeErr)
+			}
+		}()
+	}
 
 	if err := app.initTemplates(); err != nil {
 		log.Fatal("Ошибка инициализации шаблонов:", err)
@@ -303,14 +348,36 @@ func main() {
 	}
 
 	http.HandleFunc("/", app.mainPage)
-	http.HandleFunc("/new-note", app.addPage)
-	http.HandleFunc("/update-note", app.updatePage)
 	http.HandleFunc("/note", app.detailPage)
-
+	http.HandleFunc("/search", app.searchHandler)
 	http.HandleFunc("/back", app.backHandler)
-	http.HandleFunc("/create", app.createNoteHandler)
-	http.HandleFunc("/update", app.updateNoteHandler)
-	http.HandleFunc("/remove", app.removeNoteHandler)
+
+	http.HandleFunc("/api/v1/notes", app.apiNotesHandler)
+	http.HandleFunc("/api/v1/notes/", app.apiNoteHandler)
+	http.HandleFunc("/micropub", app.micropubHandler)
+
+	if app.Auth != nil {
+		http.Handle("/new-note", app.Auth.Middleware(http.HandlerFunc(app.addPage)))
+		http.Handle("/update-note", app.Auth.Middleware(http.HandlerFunc(app.updatePage)))
+		http.Handle("/create", app.Auth.Middleware(http.HandlerFunc(app.createNoteHandler)))
+		http.Handle("/update", app.Auth.Middleware(http.HandlerFunc(app.updateNoteHandler)))
+		http.Handle("/remove", app.Auth.Middleware(http.HandlerFunc(app.removeNoteHandler)))
+
+		http.HandleFunc("/login", app.Auth.LoginHandler)
+		http.HandleFunc("/callback", app.Auth.CallbackHandler)
+		http.HandleFunc("/logout", app.Auth.LogoutHandler)
+	} else {
+		// Without -me there's no owner identity to sign in as, so
+		// mutating routes fail closed instead of falling back to
+		// unauthenticated access.
+		log.Println("-me не задан: запись отключена, заметки доступны только для чтения")
+
+		http.HandleFunc("/new-note", writesDisabledHandler)
+		http.HandleFunc("/update-note", writesDisabledHandler)
+		http.HandleFunc("/create", writesDisabledHandler)
+		http.HandleFunc("/update", writesDisabledHandler)
+		http.HandleFunc("/remove", writesDisabledHandler)
+	}
 
 	fmt.Println("Сервер запущен: http://localhost:8080")
 
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// micropubEntry is the subset of the Micropub JSON syntax (an h-entry)
+// that we map onto a Note.
+type micropubEntry struct {
+	Type       []string            `json:"type"`
+	Properties map[string][]string `json:"properties"`
+}
+
+// micropubHandler creates a note from a Micropub request, accepting
+// either form-encoded (h=entry) or JSON (h-entry) bodies, so external
+// editors can post notes with a bearer token.
+func (a *App) micropubHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !a.authorizedForWrite(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var title, content string
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var entry micropubEntry
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		title = firstValue(entry.Properties["name"])
+		content = firstValue(entry.Properties["content"])
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.FormValue("h") != "entry" {
+			http.Error(w, "unsupported h-type", http.StatusBadRequest)
+			return
+		}
+		title = r.FormValue("name")
+		content = r.FormValue("content")
+	}
+
+	if content == "" {
+		http.Error(w, "content is required", http.StatusBadRequest)
+		return
+	}
+	if title == "" {
+		title = content
+		if runes := []rune(title); len(runes) > 64 {
+			title = string(runes[:64])
+		}
+	}
+
+	id, err := a.createNote(r.Context(), title, content)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/note?id=%d", id))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Melkiyjan/go-project/internal/notes"
+)
+
+const (
+	defaultPerPage = 20
+	minPerPage     = 5
+	maxPerPage     = 100
+)
+
+// pageParams is the parsed ?page=&per_page=&sort=&order= query for a
+// paginated listing. Sort/Order are passed through to notes.Repository,
+// which holds the actual column allow-list.
+type pageParams struct {
+	Page    int
+	PerPage int
+	Sort    string
+	Order   string
+}
+
+func parsePageParams(r *http.Request) pageParams {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil {
+		perPage = defaultPerPage
+	}
+	if perPage < minPerPage {
+		perPage = minPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	return pageParams{
+		Page:    page,
+		PerPage: perPage,
+		Sort:    r.URL.Query().Get("sort"),
+		Order:   r.URL.Query().Get("order"),
+	}
+}
+
+// listNotes fetches a page of notes alongside the total count, via the
+// notes repository's ListWithTotal so Total can't drift from the
+// returned page under concurrent writes.
+func (a *App) listNotes(ctx context.Context, p pageParams) ([]Note, int, error) {
+	return a.Notes.ListWithTotal(ctx, notes.ListParams{
+		Page:    p.Page,
+		PerPage: p.PerPage,
+		Sort:    p.Sort,
+		Order:   p.Order,
+	})
+}
+
+// pageWindow returns up to ±3 page numbers around page, clamped to
+// [1, totalPages], for a numbered pager.
+func pageWindow(page, totalPages int) []int {
+	const span = 3
+
+	start := page - span
+	if start < 1 {
+		start = 1
+	}
+	end := page + span
+	if end > totalPages {
+		end = totalPages
+	}
+	if end < start {
+		return nil
+	}
+
+	window := make([]int, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		window = append(window, i)
+	}
+	return window
+}
+
+func totalPages(total, perPage int) int {
+	if total == 0 {
+		return 1
+	}
+	return (total + perPage - 1) / perPage
+}
+
+// setPaginationHeaders emits a Link: rel="next" header when a further
+// page exists, so JSON clients can paginate without reading the body.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, p pageParams, pages int) {
+	if p.Page >= pages {
+		return
+	}
+
+	next := *r.URL
+	q := next.Query()
+	q.Set("page", strconv.Itoa(p.Page+1))
+	next.RawQuery = q.Encode()
+
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+}
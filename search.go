@@ -0,0 +1,191 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+const searchIndexPath = "data.bleve"
+
+// openSearchIndex opens the on-disk Bleve index, creating it with the
+// default mapping and backfilling it from the notes table if it does
+// not exist yet (e.g. first deploy, or the index dir was deleted).
+func (a *App) openSearchIndex() (bleve.Index, error) {
+	if _, err := os.Stat(searchIndexPath); os.IsNotExist(err) {
+		index, err := bleve.New(searchIndexPath, bleve.NewIndexMapping())
+		if err != nil {
+			return nil, err
+		}
+
+		count, err := a.batchIndexAll(index)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Поисковый индекс создан и заполнен: %d заметок", count)
+
+		return index, nil
+	}
+	return bleve.Open(searchIndexPath)
+}
+
+// indexNote adds or updates a note in the search index.
+func (a *App) indexNote(n Note) {
+	if a.SearchIndex == nil {
+		return
+	}
+	if err := a.SearchIndex.Index(strconv.FormatInt(n.Id, 10), n); err != nil {
+		log.Printf("Ошибка индексации заметки %d: %v", n.Id, err)
+	}
+}
+
+// deindexNote removes a note from the search index.
+func (a *App) deindexNote(id int64) {
+	if a.SearchIndex == nil {
+		return
+	}
+	if err := a.SearchIndex.Delete(strconv.FormatInt(id, 10)); err != nil {
+		log.Printf("Ошибка удаления заметки %d из индекса: %v", id, err)
+	}
+}
+
+// reindexAll drops and rebuilds the search index from the notes table.
+func (a *App) reindexAll() error {
+	if err := os.RemoveAll(searchIndexPath); err != nil {
+		return err
+	}
+
+	index, err := bleve.New(searchIndexPath, bleve.NewIndexMapping())
+	if err != nil {
+		return err
+	}
+	a.SearchIndex = index
+
+	count, err := a.batchIndexAll(index)
+	if err != nil {
+		return err
+	}
+	log.Printf("Индекс перестроен: %d заметок", count)
+	return nil
+}
+
+// batchIndexAll indexes every note in the notes table into index in a
+// single batch, returning how many notes were indexed.
+func (a *App) batchIndexAll(index bleve.Index) (int, error) {
+	rows, err := a.DB.Query("SELECT id, title, content FROM notes")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Ошибка при закрытии rows: %v", closeErr)
+		}
+	}()
+
+	batch := index.NewBatch()
+	count := 0
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.Id, &n.Title, &n.Content); err != nil {
+			return 0, err
+		}
+		if err := batch.Index(strconv.FormatInt(n.Id, 10), n); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := index.Batch(batch); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// searchHandler runs a Bleve query over the notes index and renders
+// search.html, reusing the pagination fields from IndexPageData.
+func (a *App) searchHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+
+	pageStr := r.URL.Query().Get("page")
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	data := IndexPageData{
+		Query: q,
+		Page:  page,
+	}
+
+	if q == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := a.Templates["search"].Execute(w, data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if a.SearchIndex == nil {
+		http.Error(w, "поиск недоступен", http.StatusServiceUnavailable)
+		return
+	}
+
+	const limit = 10
+	query := bleve.NewQueryStringQuery(q)
+	req := bleve.NewSearchRequestOptions(query, limit+1, (page-1)*limit, false)
+	req.Highlight = bleve.NewHighlight()
+
+	result, err := a.SearchIndex.Search(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hits := result.Hits
+	hasNext := false
+	if len(hits) > limit {
+		hasNext = true
+		hits = hits[:limit]
+	}
+
+	highlights := make(map[int64]template.HTML, len(hits))
+	ids := make([]int64, 0, len(hits))
+	for _, hit := range hits {
+		id, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+
+		for _, fragments := range hit.Fragments {
+			for _, frag := range fragments {
+				highlights[id] += template.HTML(frag) + " … "
+			}
+		}
+	}
+
+	notes, err := a.Notes.Search(r.Context(), ids)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data.Notes = notes
+	data.Highlights = highlights
+	data.HasPrev = page > 1
+	data.HasNext = hasNext
+	data.PrevPage = page - 1
+	data.NextPage = page + 1
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := a.Templates["search"].Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}